@@ -0,0 +1,123 @@
+package circuit
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"loadbalance/request"
+)
+
+// Balancer wraps a request.Balancer with a per-backend circuit Breaker,
+// skipping any backend whose breaker is Open when picking the next one.
+// Callers must invoke Report with the outcome of every request Next
+// returned a backend for, closing out the Allow call Next made for it;
+// proxy.Server does this automatically by type-asserting for a Report
+// method.
+type Balancer struct {
+	underlying request.Balancer
+	cfg        Config
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+	// pending holds one queued done callback per in-flight Allow call
+	// against a backend, in the order Next handed them out. Report pops
+	// the oldest entry for u, since which in-flight request a given Report
+	// call corresponds to doesn't matter for the breaker's sliding window
+	// - only that every Allow call eventually gets exactly one outcome.
+	pending map[string][]func(bool)
+}
+
+// NewBalancer wraps underlying, giving each of its backends a circuit
+// breaker configured by cfg.
+func NewBalancer(underlying request.Balancer, cfg Config) *Balancer {
+	return &Balancer{
+		underlying: underlying,
+		cfg:        cfg,
+		breakers:   make(map[string]*Breaker),
+		pending:    make(map[string][]func(bool)),
+	}
+}
+
+func (b *Balancer) breakerFor(u *url.URL) *Breaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br, ok := b.breakers[u.String()]
+	if !ok {
+		br = New(b.cfg)
+		b.breakers[u.String()] = br
+	}
+	return br
+}
+
+// Next asks the underlying Balancer for a backend, skipping any whose
+// breaker denies the request (it is Open, or already probing in
+// HalfOpen), up to once per distinct backend.
+func (b *Balancer) Next(r *http.Request) *url.URL {
+	seen := make(map[string]struct{})
+
+	for i := 0; i < len(b.underlying.Servers())+1; i++ {
+		u := b.underlying.Next(r)
+		if u == nil {
+			return nil
+		}
+		if _, ok := seen[u.String()]; ok {
+			continue
+		}
+		seen[u.String()] = struct{}{}
+
+		allowed, done := b.breakerFor(u).Allow()
+		if !allowed {
+			continue
+		}
+
+		key := u.String()
+		b.mu.Lock()
+		b.pending[key] = append(b.pending[key], done)
+		b.mu.Unlock()
+		return u
+	}
+	return nil
+}
+
+// Report records the outcome of a request dispatched to u, closing out one
+// of the Allow calls Next made for it. Concurrent requests to the same
+// backend each queue their own done callback in Next, so Report always
+// closes out one pending call per invocation rather than clobbering
+// another in-flight request's callback.
+func (b *Balancer) Report(u *url.URL, success bool) {
+	key := u.String()
+
+	b.mu.Lock()
+	var done func(bool)
+	if queue := b.pending[key]; len(queue) > 0 {
+		done = queue[0]
+		if len(queue) == 1 {
+			delete(b.pending, key)
+		} else {
+			b.pending[key] = queue[1:]
+		}
+	}
+	b.mu.Unlock()
+
+	if done != nil {
+		done(success)
+	}
+}
+
+func (b *Balancer) Add(u *url.URL, opts ...request.ServerOption) {
+	b.underlying.Add(u, opts...)
+}
+
+func (b *Balancer) Remove(u *url.URL) {
+	b.underlying.Remove(u)
+}
+
+func (b *Balancer) MarkUnhealthy(u *url.URL) {
+	b.underlying.MarkUnhealthy(u)
+}
+
+func (b *Balancer) Servers() []*url.URL {
+	return b.underlying.Servers()
+}
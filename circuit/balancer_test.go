@@ -0,0 +1,44 @@
+package circuit
+
+import (
+	"net/url"
+	"testing"
+
+	"loadbalance/request"
+)
+
+// TestBalancerReportsEveryConcurrentOutcome drives 20 overlapping
+// Next/Report pairs against a single backend - Next is called for all of
+// them before any Report runs, the way 20 concurrent requests to the same
+// backend would overlap in proxy.Server. Every one of those 20 Allow calls
+// must get its own outcome recorded, not just the last Next to run.
+func TestBalancerReportsEveryConcurrentOutcome(t *testing.T) {
+	backend, err := url.Parse("http://localhost:81")
+	if err != nil {
+		t.Fatalf("Failed to parse backend URL: %v", err)
+	}
+
+	balancer := NewBalancer(request.NewRoundRobin([]*url.URL{backend}), Config{
+		WindowSize:       20,
+		MinRequests:      20,
+		FailureThreshold: 0.5,
+	})
+
+	const n = 20
+	urls := make([]*url.URL, n)
+	for i := 0; i < n; i++ {
+		urls[i] = balancer.Next(nil)
+		if urls[i] == nil {
+			t.Fatalf("iteration %d: expected a backend to be allowed", i)
+		}
+	}
+	for i := 0; i < n; i++ {
+		balancer.Report(urls[i], false)
+	}
+
+	// All 20 failures should have been recorded, not just the one whose
+	// Report call happened to still find its callback queued.
+	if got := balancer.Next(nil); got != nil {
+		t.Errorf("expected the breaker to have tripped on %d recorded failures, got %s", n, got)
+	}
+}
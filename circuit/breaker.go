@@ -0,0 +1,220 @@
+package circuit
+
+import (
+	"sync"
+	"time"
+)
+
+// State is one of the three states a Breaker can be in.
+type State int
+
+const (
+	// Closed lets all traffic through, counting failures into the
+	// sliding window.
+	Closed State = iota
+	// Open short-circuits all requests until CooldownDuration elapses.
+	Open
+	// HalfOpen admits exactly one probe request to decide whether to
+	// close the breaker again or re-open it.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config controls a Breaker's sliding window and cooldown behavior.
+type Config struct {
+	// WindowSize is how many of the most recent outcomes are kept to
+	// compute the failure rate. Defaults to 10.
+	WindowSize int
+	// MinRequests is the minimum number of outcomes in the window before
+	// the failure rate is evaluated at all, so a handful of early
+	// failures can't trip the breaker by themselves. Defaults to 5.
+	MinRequests int
+	// FailureThreshold is the failure rate (0-1) that must be exceeded,
+	// with at least MinRequests samples, to trip the breaker. Defaults
+	// to 0.5.
+	FailureThreshold float64
+	// CooldownDuration is how long the breaker stays Open before
+	// admitting a half-open probe, the first time it trips. Defaults to
+	// 5s.
+	CooldownDuration time.Duration
+	// MaxCooldown caps the exponential backoff applied to
+	// CooldownDuration each time a half-open probe fails. Defaults to
+	// 10 * CooldownDuration.
+	MaxCooldown time.Duration
+}
+
+const (
+	defaultWindowSize       = 10
+	defaultMinRequests      = 5
+	defaultFailureThreshold = 0.5
+	defaultCooldown         = 5 * time.Second
+)
+
+func (cfg Config) withDefaults() Config {
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = defaultWindowSize
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = defaultMinRequests
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultFailureThreshold
+	}
+	if cfg.CooldownDuration <= 0 {
+		cfg.CooldownDuration = defaultCooldown
+	}
+	if cfg.MaxCooldown <= 0 {
+		cfg.MaxCooldown = 10 * cfg.CooldownDuration
+	}
+	return cfg
+}
+
+// Breaker is a per-backend circuit breaker over a sliding window of the
+// most recent outcomes.
+type Breaker struct {
+	cfg   Config
+	clock Clock
+
+	mu               sync.Mutex
+	state            State
+	outcomes         []bool
+	pos              int
+	filled           int
+	successCount     int
+	failureCount     int
+	cooldown         time.Duration
+	cooldownUntil    time.Time
+	halfOpenInFlight bool
+}
+
+// New builds a Breaker in the Closed state.
+func New(cfg Config) *Breaker {
+	cfg = cfg.withDefaults()
+	return &Breaker{
+		cfg:      cfg,
+		clock:    realClock{},
+		outcomes: make([]bool, cfg.WindowSize),
+	}
+}
+
+// State returns the breaker's current state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow reports whether a request may proceed. If it may, the caller must
+// invoke done with the outcome once the request completes.
+func (b *Breaker) Allow() (bool, func(success bool)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if b.clock.Now().Before(b.cooldownUntil) {
+			return false, func(bool) {}
+		}
+		b.state = HalfOpen
+		b.halfOpenInFlight = true
+		return true, b.done
+	case HalfOpen:
+		if b.halfOpenInFlight {
+			return false, func(bool) {}
+		}
+		b.halfOpenInFlight = true
+		return true, b.done
+	default:
+		return true, b.done
+	}
+}
+
+func (b *Breaker) done(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case HalfOpen:
+		b.halfOpenInFlight = false
+		if success {
+			b.closeLocked()
+		} else {
+			b.tripLocked()
+		}
+	default:
+		b.record(success)
+		if b.state == Closed && b.shouldTripLocked() {
+			b.tripLocked()
+		}
+	}
+}
+
+// record appends success into the ring buffer, evicting the oldest
+// outcome once the window is full.
+func (b *Breaker) record(success bool) {
+	if b.filled == len(b.outcomes) {
+		if b.outcomes[b.pos] {
+			b.successCount--
+		} else {
+			b.failureCount--
+		}
+	} else {
+		b.filled++
+	}
+
+	b.outcomes[b.pos] = success
+	if success {
+		b.successCount++
+	} else {
+		b.failureCount++
+	}
+	b.pos = (b.pos + 1) % len(b.outcomes)
+}
+
+func (b *Breaker) shouldTripLocked() bool {
+	total := b.successCount + b.failureCount
+	if total < b.cfg.MinRequests {
+		return false
+	}
+	return float64(b.failureCount)/float64(total) > b.cfg.FailureThreshold
+}
+
+// tripLocked opens the breaker, doubling the cooldown from the last time
+// it tripped (capped at MaxCooldown) or starting at CooldownDuration the
+// first time.
+func (b *Breaker) tripLocked() {
+	if b.cooldown == 0 {
+		b.cooldown = b.cfg.CooldownDuration
+	} else {
+		b.cooldown *= 2
+		if b.cooldown > b.cfg.MaxCooldown {
+			b.cooldown = b.cfg.MaxCooldown
+		}
+	}
+
+	b.state = Open
+	b.cooldownUntil = b.clock.Now().Add(b.cooldown)
+	b.outcomes = make([]bool, len(b.outcomes))
+	b.pos, b.filled, b.successCount, b.failureCount = 0, 0, 0, 0
+}
+
+// closeLocked closes the breaker and resets its backoff, so the next trip
+// starts again at CooldownDuration.
+func (b *Breaker) closeLocked() {
+	b.state = Closed
+	b.cooldown = 0
+	b.outcomes = make([]bool, len(b.outcomes))
+	b.pos, b.filled, b.successCount, b.failureCount = 0, 0, 0, 0
+}
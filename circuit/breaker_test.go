@@ -0,0 +1,144 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock lets tests advance time deterministically instead of sleeping.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBreaker(clock *fakeClock, cfg Config) *Breaker {
+	b := New(cfg)
+	b.clock = clock
+	return b
+}
+
+func allowAndReport(b *Breaker, success bool) bool {
+	allowed, done := b.Allow()
+	if allowed {
+		done(success)
+	}
+	return allowed
+}
+
+func TestBreakerTripsOnFailureRate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newTestBreaker(clock, Config{
+		WindowSize:       4,
+		MinRequests:      4,
+		FailureThreshold: 0.5,
+		CooldownDuration: time.Second,
+	})
+
+	allowAndReport(b, true)
+	allowAndReport(b, false)
+	allowAndReport(b, false)
+	if b.State() != Closed {
+		t.Fatalf("expected Closed before crossing the threshold, got %s", b.State())
+	}
+
+	allowAndReport(b, false)
+	if b.State() != Open {
+		t.Fatalf("expected Open once failures exceed the threshold, got %s", b.State())
+	}
+
+	if allowed, _ := b.Allow(); allowed {
+		t.Error("expected Open breaker to deny requests during cooldown")
+	}
+}
+
+func TestBreakerHalfOpenClosesOnSuccessfulProbe(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newTestBreaker(clock, Config{
+		WindowSize:       2,
+		MinRequests:      2,
+		FailureThreshold: 0.5,
+		CooldownDuration: time.Second,
+	})
+
+	allowAndReport(b, false)
+	allowAndReport(b, false)
+	if b.State() != Open {
+		t.Fatalf("expected Open, got %s", b.State())
+	}
+
+	clock.Advance(time.Second)
+
+	allowed, done := b.Allow()
+	if !allowed {
+		t.Fatal("expected a probe to be allowed once cooldown elapses")
+	}
+	if b.State() != HalfOpen {
+		t.Fatalf("expected HalfOpen while the probe is in flight, got %s", b.State())
+	}
+
+	done(true)
+	if b.State() != Closed {
+		t.Fatalf("expected Closed after a successful probe, got %s", b.State())
+	}
+}
+
+func TestBreakerHalfOpenReopensAndBacksOffOnFailedProbe(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newTestBreaker(clock, Config{
+		WindowSize:       2,
+		MinRequests:      2,
+		FailureThreshold: 0.5,
+		CooldownDuration: time.Second,
+		MaxCooldown:      10 * time.Second,
+	})
+
+	allowAndReport(b, false)
+	allowAndReport(b, false)
+
+	clock.Advance(time.Second)
+	allowed, done := b.Allow()
+	if !allowed {
+		t.Fatal("expected the first probe to be allowed")
+	}
+	done(false)
+	if b.State() != Open {
+		t.Fatalf("expected Open after a failed probe, got %s", b.State())
+	}
+
+	// The cooldown should have doubled to 2s: 1s isn't enough yet.
+	clock.Advance(time.Second)
+	if allowed, _ := b.Allow(); allowed {
+		t.Error("expected the doubled cooldown to still be in effect after 1s")
+	}
+
+	clock.Advance(time.Second)
+	if allowed, _ := b.Allow(); !allowed {
+		t.Error("expected a new probe to be allowed once the doubled cooldown elapses")
+	}
+}
+
+func TestBreakerOnlyAdmitsOneHalfOpenProbeAtATime(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newTestBreaker(clock, Config{
+		WindowSize:       2,
+		MinRequests:      2,
+		FailureThreshold: 0.5,
+		CooldownDuration: time.Second,
+	})
+
+	allowAndReport(b, false)
+	allowAndReport(b, false)
+	clock.Advance(time.Second)
+
+	allowed, _ := b.Allow()
+	if !allowed {
+		t.Fatal("expected the first probe to be allowed")
+	}
+
+	if allowed, _ := b.Allow(); allowed {
+		t.Error("expected a second concurrent probe to be denied while one is in flight")
+	}
+}
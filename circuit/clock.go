@@ -0,0 +1,13 @@
+package circuit
+
+import "time"
+
+// Clock abstracts time.Now so breaker cooldowns can be tested without
+// sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
@@ -1,9 +1,18 @@
 package main
 
 import (
-	"loadbalance/request"
+	"context"
+	"log"
+	"net/http"
 	"net/url"
+	"os/signal"
+	"syscall"
 	"time"
+
+	"loadbalance/circuit"
+	"loadbalance/healthcheck"
+	"loadbalance/proxy"
+	"loadbalance/request"
 )
 
 func main() {
@@ -22,19 +31,32 @@ func main() {
 		},
 	}
 
-	balancer := request.NewRoundRobinBalancer(backends)
+	balancer := circuit.NewBalancer(request.NewRoundRobin(backends), circuit.Config{})
+
+	checker := healthcheck.New(balancer, backends, healthcheck.Config{
+		Interval: 5 * time.Second,
+		Timeout:  2 * time.Second,
+	})
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	checker.Start(ctx)
+	defer checker.Stop()
+
+	server := proxy.NewServer(balancer, proxy.Config{
+		Addr:         ":8080",
+		MaxRetries:   2,
+		DrainTimeout: 30 * time.Second,
+	})
 
-	// Periodically check and restore removed servers
 	go func() {
-		for {
-			time.Sleep(5 * time.Second)
-			balancer.CheckAndRestoreUrls()
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("proxy: ListenAndServe: %v", err)
 		}
 	}()
 
-	// Continuously send requests
-	for {
-		request.SendRequest(balancer)
-		time.Sleep(1 * time.Second) // Throttle requests
+	<-ctx.Done()
+	log.Println("shutting down, draining in-flight requests")
+	if err := server.Shutdown(); err != nil {
+		log.Printf("proxy: error during shutdown: %v", err)
 	}
 }
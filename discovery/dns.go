@@ -0,0 +1,95 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DNSProvider discovers backends from DNS SRV records, polled every
+// interval. SRV weight becomes the backend's Weight, which a Reconciler
+// passes to weight-aware balancers such as request.WeightedRoundRobin; SRV
+// priority is only recorded as "priority" metadata, and no balancer in
+// this module orders backends by it.
+type DNSProvider struct {
+	service  string
+	proto    string
+	name     string
+	interval time.Duration
+	resolver *net.Resolver
+}
+
+// NewDNSProvider builds a DNSProvider that looks up
+// _service._proto.name SRV records every interval.
+func NewDNSProvider(service, proto, name string, interval time.Duration) *DNSProvider {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	return &DNSProvider{
+		service:  service,
+		proto:    proto,
+		name:     name,
+		interval: interval,
+		resolver: net.DefaultResolver,
+	}
+}
+
+func (d *DNSProvider) Watch(ctx context.Context) <-chan []Backend {
+	ch := make(chan []Backend)
+	go d.run(ctx, ch)
+	return ch
+}
+
+func (d *DNSProvider) run(ctx context.Context, ch chan<- []Backend) {
+	defer close(ch)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	var last string
+	emit := func() {
+		_, records, err := d.resolver.LookupSRV(ctx, d.service, d.proto, d.name)
+		if err != nil {
+			log.Printf("discovery: dns provider: lookup %s: %v", d.name, err)
+			return
+		}
+
+		backends := make([]Backend, 0, len(records))
+		for _, rec := range records {
+			target := strings.TrimSuffix(rec.Target, ".")
+			backends = append(backends, Backend{
+				URL:    &url.URL{Scheme: "http", Host: fmt.Sprintf("%s:%d", target, rec.Port)},
+				Weight: int(rec.Weight),
+				Metadata: map[string]string{
+					"priority": strconv.Itoa(int(rec.Priority)),
+				},
+			})
+		}
+
+		key := fmt.Sprint(backends)
+		if key == last {
+			return
+		}
+		last = key
+
+		select {
+		case ch <- backends:
+		case <-ctx.Done():
+		}
+	}
+
+	emit()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			emit()
+		}
+	}
+}
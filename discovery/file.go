@@ -0,0 +1,84 @@
+package discovery
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// defaultPollInterval is used by FileProvider when none is given.
+const defaultPollInterval = 2 * time.Second
+
+// FileProvider watches a JSON config file on disk (see backendList in
+// schema.go for the shape) and republishes its contents whenever its
+// modification time changes. The module has no vendored file-watcher
+// dependency, so this polls os.Stat every pollInterval rather than using
+// inotify/fsnotify directly; that is enough to hot-reload without
+// dropping in-flight requests, since existing backends keep serving until
+// the Reconciler removes them.
+type FileProvider struct {
+	path         string
+	pollInterval time.Duration
+}
+
+// NewFileProvider builds a FileProvider for the config file at path,
+// polled every pollInterval (defaultPollInterval if zero).
+func NewFileProvider(path string, pollInterval time.Duration) *FileProvider {
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	return &FileProvider{path: path, pollInterval: pollInterval}
+}
+
+func (f *FileProvider) Watch(ctx context.Context) <-chan []Backend {
+	ch := make(chan []Backend)
+	go f.run(ctx, ch)
+	return ch
+}
+
+func (f *FileProvider) run(ctx context.Context, ch chan<- []Backend) {
+	defer close(ch)
+
+	ticker := time.NewTicker(f.pollInterval)
+	defer ticker.Stop()
+
+	var lastModTime time.Time
+	emit := func() {
+		info, err := os.Stat(f.path)
+		if err != nil {
+			log.Printf("discovery: file provider: stat %s: %v", f.path, err)
+			return
+		}
+		if !info.ModTime().After(lastModTime) {
+			return
+		}
+
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			log.Printf("discovery: file provider: read %s: %v", f.path, err)
+			return
+		}
+		backends, err := decodeBackendsJSON(data)
+		if err != nil {
+			log.Printf("discovery: file provider: decode %s: %v", f.path, err)
+			return
+		}
+
+		lastModTime = info.ModTime()
+		select {
+		case ch <- backends:
+		case <-ctx.Done():
+		}
+	}
+
+	emit()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			emit()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package discovery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+}
+
+func TestFileProviderPublishesInitialBackends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backends.json")
+	writeConfig(t, path, `{"backends":[{"url":"http://localhost:81","weight":2}]}`)
+
+	provider := NewFileProvider(path, 20*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	select {
+	case backends := <-provider.Watch(ctx):
+		if len(backends) != 1 || backends[0].URL.String() != "http://localhost:81" {
+			t.Fatalf("unexpected backends: %+v", backends)
+		}
+		if backends[0].Weight != 2 {
+			t.Errorf("expected weight 2, got %d", backends[0].Weight)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for initial backends")
+	}
+}
+
+func TestFileProviderHotReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backends.json")
+	writeConfig(t, path, `{"backends":[{"url":"http://localhost:81"}]}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	provider := NewFileProvider(path, 20*time.Millisecond)
+	ch := provider.Watch(ctx)
+
+	<-ch // initial read
+
+	// Rewriting the file should not drop the channel's prior readers;
+	// existing in-flight requests to localhost:81 are unaffected by the
+	// reconciler picking this up later.
+	time.Sleep(10 * time.Millisecond)
+	writeConfig(t, path, `{"backends":[{"url":"http://localhost:82"}]}`)
+
+	select {
+	case backends := <-ch:
+		if len(backends) != 1 || backends[0].URL.String() != "http://localhost:82" {
+			t.Fatalf("expected the reloaded backend, got %+v", backends)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the reloaded backends")
+	}
+}
@@ -0,0 +1,181 @@
+package discovery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// kvProvider polls a KV store's HTTP API for a key holding a JSON-encoded
+// backendList (see schema.go) and republishes the decoded set whenever the
+// raw value changes. Consul and etcd expose different HTTP shapes for
+// "read this key"; fetch hides that difference so the polling and
+// change-detection logic can be shared. This polls on interval rather than
+// using Consul's blocking queries (?index=) or etcd's watch stream
+// (/v3/watch) - see the "Known deviations" note in examples/README.md.
+type kvProvider struct {
+	interval time.Duration
+	fetch    func(ctx context.Context) (string, error)
+}
+
+func (p *kvProvider) Watch(ctx context.Context) <-chan []Backend {
+	ch := make(chan []Backend)
+	go p.run(ctx, ch)
+	return ch
+}
+
+func (p *kvProvider) run(ctx context.Context, ch chan<- []Backend) {
+	defer close(ch)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	var last string
+	emit := func() {
+		raw, err := p.fetch(ctx)
+		if err != nil {
+			log.Printf("discovery: kv provider: %v", err)
+			return
+		}
+		if raw == last {
+			return
+		}
+
+		backends, err := decodeBackendsJSON([]byte(raw))
+		if err != nil {
+			log.Printf("discovery: kv provider: decode: %v", err)
+			return
+		}
+
+		last = raw
+		select {
+		case ch <- backends:
+		case <-ctx.Done():
+		}
+	}
+
+	emit()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			emit()
+		}
+	}
+}
+
+// NewConsulProvider polls a Consul KV key (e.g. "loadbalance/backends")
+// for a JSON-encoded backendList via Consul's HTTP API
+// (GET /v1/kv/<key>?raw=true). It polls on interval rather than using a
+// Consul blocking query.
+func NewConsulProvider(addr, key string, interval time.Duration) Provider {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	reqURL := strings.TrimRight(addr, "/") + "/v1/kv/" + strings.TrimLeft(key, "/") + "?raw=true"
+
+	return &kvProvider{
+		interval: interval,
+		fetch: func(ctx context.Context) (string, error) {
+			return httpGetString(ctx, client, reqURL)
+		},
+	}
+}
+
+// NewEtcdProvider polls an etcd key for a JSON-encoded backendList via
+// etcd's gRPC-gateway HTTP API (POST /v3/kv/range), base64-decoding the
+// value field of the single key in the response. It polls on interval
+// rather than opening an etcd watch stream.
+func NewEtcdProvider(addr, key string, interval time.Duration) Provider {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	reqURL := strings.TrimRight(addr, "/") + "/v3/kv/range"
+	body, _ := json.Marshal(map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(key))})
+
+	return &kvProvider{
+		interval: interval,
+		fetch: func(ctx context.Context) (string, error) {
+			respBody, err := httpPost(ctx, client, reqURL, body)
+			if err != nil {
+				return "", err
+			}
+			return decodeEtcdRangeValue(respBody)
+		},
+	}
+}
+
+type etcdRangeResponse struct {
+	Kvs []struct {
+		Value string `json:"value"`
+	} `json:"kvs"`
+}
+
+func decodeEtcdRangeValue(respBody []byte) (string, error) {
+	var resp etcdRangeResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return "", fmt.Errorf("decode etcd range response: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return "", fmt.Errorf("etcd key not found")
+	}
+
+	value, err := base64.StdEncoding.DecodeString(resp.Kvs[0].Value)
+	if err != nil {
+		return "", fmt.Errorf("decode etcd value: %w", err)
+	}
+	return string(value), nil
+}
+
+func httpGetString(ctx context.Context, client *http.Client, reqURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d from %s", resp.StatusCode, reqURL)
+	}
+	return string(body), nil
+}
+
+func httpPost(ctx context.Context, client *http.Client, reqURL string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, strings.NewReader(string(body)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, reqURL)
+	}
+	return respBody, nil
+}
@@ -0,0 +1,29 @@
+// Package discovery supplies backend sets from external sources (a config
+// file, DNS SRV records, a Consul/etcd KV key) so operators no longer edit
+// a hard-coded slice of backends in main, and wires them into a
+// request.Balancer via a Reconciler.
+package discovery
+
+import (
+	"context"
+	"net/url"
+)
+
+// Backend is a single backend as reported by a Provider: its URL, its
+// relative weight (fed into weight-aware balancers such as
+// request.WeightedRoundRobin), and arbitrary metadata a consumer can use
+// for routing decisions (e.g. header-based routing).
+type Backend struct {
+	URL      *url.URL
+	Weight   int
+	Metadata map[string]string
+}
+
+// Provider watches an external source of backends and publishes the
+// complete current set on the returned channel every time it changes.
+type Provider interface {
+	// Watch starts watching and returns a channel that receives the full
+	// set of backends whenever it changes. The channel is closed once
+	// ctx is cancelled.
+	Watch(ctx context.Context) <-chan []Backend
+}
@@ -0,0 +1,59 @@
+package discovery
+
+import (
+	"context"
+
+	"loadbalance/request"
+)
+
+// Reconciler keeps a request.Balancer's rotation in sync with the set of
+// backends a Provider reports, issuing Add calls for backends that
+// appeared and Remove calls for backends that disappeared, instead of
+// requiring operators to edit a hard-coded slice. A new weight reported
+// for a backend already in rotation is not applied.
+type Reconciler struct {
+	balancer request.Balancer
+}
+
+// NewReconciler builds a Reconciler that drives balancer.
+func NewReconciler(balancer request.Balancer) *Reconciler {
+	return &Reconciler{balancer: balancer}
+}
+
+// Run reconciles every backend set provider publishes until ctx is
+// cancelled or the provider's channel closes.
+func (r *Reconciler) Run(ctx context.Context, provider Provider) {
+	for backends := range provider.Watch(ctx) {
+		r.reconcile(backends)
+	}
+}
+
+func (r *Reconciler) reconcile(backends []Backend) {
+	want := make(map[string]Backend, len(backends))
+	for _, b := range backends {
+		want[b.URL.String()] = b
+	}
+
+	have := make(map[string]struct{})
+	for _, u := range r.balancer.Servers() {
+		have[u.String()] = struct{}{}
+	}
+
+	for key, b := range want {
+		if _, ok := have[key]; !ok {
+			r.balancer.Add(b.URL, request.Weight(weightOrDefault(b.Weight)))
+		}
+	}
+	for _, u := range r.balancer.Servers() {
+		if _, ok := want[u.String()]; !ok {
+			r.balancer.Remove(u)
+		}
+	}
+}
+
+func weightOrDefault(w int) int {
+	if w <= 0 {
+		return 1
+	}
+	return w
+}
@@ -0,0 +1,43 @@
+package discovery
+
+import (
+	"net/url"
+	"testing"
+
+	"loadbalance/request"
+)
+
+func TestReconcilerAddsAndRemoves(t *testing.T) {
+	kept, err := url.Parse("http://localhost:81")
+	if err != nil {
+		t.Fatalf("Failed to parse URL: %v", err)
+	}
+	stale, err := url.Parse("http://localhost:82")
+	if err != nil {
+		t.Fatalf("Failed to parse URL: %v", err)
+	}
+	fresh, err := url.Parse("http://localhost:83")
+	if err != nil {
+		t.Fatalf("Failed to parse URL: %v", err)
+	}
+
+	balancer := request.NewRoundRobin([]*url.URL{kept, stale})
+	reconciler := NewReconciler(balancer)
+
+	reconciler.reconcile([]Backend{{URL: kept}, {URL: fresh}})
+
+	servers := map[string]struct{}{}
+	for _, u := range balancer.Servers() {
+		servers[u.String()] = struct{}{}
+	}
+
+	if _, ok := servers[kept.String()]; !ok {
+		t.Errorf("expected %s to remain in rotation", kept)
+	}
+	if _, ok := servers[fresh.String()]; !ok {
+		t.Errorf("expected %s to be added to rotation", fresh)
+	}
+	if _, ok := servers[stale.String()]; ok {
+		t.Errorf("expected %s to be removed from rotation", stale)
+	}
+}
@@ -0,0 +1,50 @@
+package discovery
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+)
+
+// backendList is the JSON schema shared by the file and KV providers: a
+// flat list of backends, each with an optional weight and metadata.
+//
+//	{
+//	  "backends": [
+//	    {"url": "http://10.0.0.1:8080", "weight": 2, "metadata": {"region": "us-east"}},
+//	    {"url": "http://10.0.0.2:8080"}
+//	  ]
+//	}
+type backendList struct {
+	Backends []backendEntry `json:"backends"`
+}
+
+type backendEntry struct {
+	URL      string            `json:"url"`
+	Weight   int               `json:"weight"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// decodeBackendsJSON parses the backendList schema, skipping (and logging)
+// any entry whose URL does not parse rather than failing the whole batch.
+func decodeBackendsJSON(data []byte) ([]Backend, error) {
+	var list backendList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	backends := make([]Backend, 0, len(list.Backends))
+	for _, entry := range list.Backends {
+		u, err := url.Parse(entry.URL)
+		if err != nil {
+			log.Printf("discovery: invalid backend URL %q: %v", entry.URL, err)
+			continue
+		}
+		backends = append(backends, Backend{
+			URL:      u,
+			Weight:   entry.Weight,
+			Metadata: entry.Metadata,
+		})
+	}
+	return backends, nil
+}
@@ -0,0 +1,109 @@
+package healthcheck
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config controls how a HealthChecker probes a single backend. It follows
+// the shape of Traefik's health check configuration: a request is sent on
+// every Interval, a response must arrive within Timeout and match one of
+// AcceptedStatusCodes, and Rise/Fall consecutive results are required
+// before a backend's health actually flips, so a single blip does not
+// eject it and a single recovery does not restore it.
+type Config struct {
+	// Interval between checks. Defaults to 10s.
+	Interval time.Duration
+	// Timeout for a single check request. Defaults to 5s.
+	Timeout time.Duration
+	// Path requested on the backend. Defaults to "/".
+	Path string
+	// Method used for the check request. Defaults to GET.
+	Method string
+	// Hostname overrides the Host header sent with the check request.
+	Hostname string
+	// Headers are added to every check request.
+	Headers http.Header
+	// AcceptedStatusCodes lists the status codes (e.g. "200", "204", or a
+	// range like "300-399") that count as a successful check. Defaults to
+	// []string{"200", "204", "300-399"}.
+	AcceptedStatusCodes []string
+	// Rise is the number of consecutive successful checks required before
+	// an unhealthy backend is restored. Defaults to 1.
+	Rise int
+	// Fall is the number of consecutive failed checks required before a
+	// healthy backend is ejected. Defaults to 1.
+	Fall int
+}
+
+const (
+	defaultInterval = 10 * time.Second
+	defaultTimeout  = 5 * time.Second
+	defaultPath     = "/"
+	defaultMethod   = http.MethodGet
+	defaultRise     = 1
+	defaultFall     = 1
+)
+
+var defaultAcceptedStatusCodes = []string{"200", "204", "300-399"}
+
+// withDefaults returns a copy of cfg with zero-valued fields replaced by
+// their defaults.
+func (cfg Config) withDefaults() Config {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.Path == "" {
+		cfg.Path = defaultPath
+	}
+	if cfg.Method == "" {
+		cfg.Method = defaultMethod
+	}
+	if cfg.Rise <= 0 {
+		cfg.Rise = defaultRise
+	}
+	if cfg.Fall <= 0 {
+		cfg.Fall = defaultFall
+	}
+	if len(cfg.AcceptedStatusCodes) == 0 {
+		cfg.AcceptedStatusCodes = defaultAcceptedStatusCodes
+	}
+	return cfg
+}
+
+// accepts reports whether statusCode matches one of cfg.AcceptedStatusCodes.
+func (cfg Config) accepts(statusCode int) bool {
+	for _, rng := range cfg.AcceptedStatusCodes {
+		lo, hi, ok := parseStatusRange(rng)
+		if !ok {
+			continue
+		}
+		if statusCode >= lo && statusCode <= hi {
+			return true
+		}
+	}
+	return false
+}
+
+// parseStatusRange parses "200" into (200, 200) and "300-399" into
+// (300, 399).
+func parseStatusRange(rng string) (lo, hi int, ok bool) {
+	parts := strings.SplitN(rng, "-", 2)
+	lo, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+	if len(parts) == 1 {
+		return lo, lo, true
+	}
+	hi, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
@@ -0,0 +1,176 @@
+package healthcheck
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"loadbalance/request"
+)
+
+// targetState holds the per-backend bookkeeping a HealthChecker needs
+// between ticks: how many consecutive successes/failures it has seen and
+// whether the backend is currently considered healthy.
+type targetState struct {
+	healthy            bool
+	consecutiveSuccess int
+	consecutiveFailure int
+}
+
+// HealthChecker asynchronously probes every backend it is given and drives
+// a request.Balancer by calling MarkUnhealthy after Fall consecutive
+// failures and Add after Rise consecutive successes.
+type HealthChecker struct {
+	balancer request.Balancer
+	targets  []*url.URL
+	cfg      Config
+	client   *http.Client
+
+	mu     sync.Mutex
+	states map[string]*targetState
+	gauges map[string]float64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New builds a HealthChecker that probes targets and reports their health
+// to balancer. Backends start in the healthy state, matching the
+// assumption that the balancer was seeded with servers believed to be up.
+func New(balancer request.Balancer, targets []*url.URL, cfg Config) *HealthChecker {
+	cfg = cfg.withDefaults()
+
+	states := make(map[string]*targetState, len(targets))
+	gauges := make(map[string]float64, len(targets))
+	for _, target := range targets {
+		states[target.String()] = &targetState{healthy: true}
+		gauges[target.String()] = 1
+	}
+
+	return &HealthChecker{
+		balancer: balancer,
+		targets:  targets,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		states:   states,
+		gauges:   gauges,
+	}
+}
+
+// Start launches one probing goroutine per target. It returns immediately;
+// call Stop (or cancel ctx) to stop probing.
+func (h *HealthChecker) Start(ctx context.Context) {
+	ctx, h.cancel = context.WithCancel(ctx)
+
+	for _, target := range h.targets {
+		target := target
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			h.run(ctx, target)
+		}()
+	}
+}
+
+// Stop stops all probing goroutines and waits for them to exit.
+func (h *HealthChecker) Stop() {
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.wg.Wait()
+}
+
+// Gauge returns the last reported health value for target: 1 if healthy,
+// 0 if unhealthy. It mirrors what a Prometheus gauge metric would expose
+// per backend.
+func (h *HealthChecker) Gauge(target *url.URL) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.gauges[target.String()]
+}
+
+func (h *HealthChecker) run(ctx context.Context, target *url.URL) {
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		h.check(ctx, target)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *HealthChecker) check(ctx context.Context, target *url.URL) {
+	ok := h.probe(ctx, target)
+
+	h.mu.Lock()
+	state := h.states[target.String()]
+	var becameHealthy, becameUnhealthy bool
+	if ok {
+		state.consecutiveSuccess++
+		state.consecutiveFailure = 0
+		if !state.healthy && state.consecutiveSuccess >= h.cfg.Rise {
+			state.healthy = true
+			becameHealthy = true
+		}
+	} else {
+		state.consecutiveFailure++
+		state.consecutiveSuccess = 0
+		if state.healthy && state.consecutiveFailure >= h.cfg.Fall {
+			state.healthy = false
+			becameUnhealthy = true
+		}
+	}
+	if state.healthy {
+		h.gauges[target.String()] = 1
+	} else {
+		h.gauges[target.String()] = 0
+	}
+	h.mu.Unlock()
+
+	if becameHealthy {
+		log.Printf("healthcheck: %s is healthy again, restoring to rotation", target)
+		h.balancer.Add(target)
+	}
+	if becameUnhealthy {
+		log.Printf("healthcheck: %s failed %d consecutive checks, removing from rotation", target, h.cfg.Fall)
+		h.balancer.MarkUnhealthy(target)
+	}
+}
+
+func (h *HealthChecker) probe(ctx context.Context, target *url.URL) bool {
+	checkURL := *target
+	checkURL.Path = h.cfg.Path
+
+	reqCtx, cancel := context.WithTimeout(ctx, h.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, h.cfg.Method, checkURL.String(), nil)
+	if err != nil {
+		log.Printf("healthcheck: error creating request for %s: %v", target, err)
+		return false
+	}
+	if h.cfg.Hostname != "" {
+		req.Host = h.cfg.Hostname
+	}
+	for key, values := range h.cfg.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return h.cfg.accepts(resp.StatusCode)
+}
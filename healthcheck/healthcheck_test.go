@@ -0,0 +1,153 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"loadbalance/request"
+)
+
+// scriptedServer returns a test server that replies with the status codes
+// in sequence, repeating the last one once the sequence is exhausted.
+func scriptedServer(t *testing.T, sequence []int) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	i := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		status := sequence[i]
+		if i < len(sequence)-1 {
+			i++
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+}
+
+// fakeBalancer records Add/MarkUnhealthy calls for a single backend so
+// tests can assert on the transitions a HealthChecker drives.
+type fakeBalancer struct {
+	mu      sync.Mutex
+	healthy bool
+}
+
+func (f *fakeBalancer) Next(*http.Request) *url.URL { return nil }
+
+func (f *fakeBalancer) Add(*url.URL, ...request.ServerOption) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.healthy = true
+}
+
+func (f *fakeBalancer) Remove(*url.URL) {}
+
+func (f *fakeBalancer) MarkUnhealthy(*url.URL) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.healthy = false
+}
+
+func (f *fakeBalancer) Servers() []*url.URL { return nil }
+
+func (f *fakeBalancer) isHealthy() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.healthy
+}
+
+func TestHealthCheckerEjectsAfterFallFailures(t *testing.T) {
+	server := scriptedServer(t, []int{200, 500, 500})
+	defer server.Close()
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+
+	balancer := &fakeBalancer{healthy: true}
+	checker := New(balancer, []*url.URL{target}, Config{
+		Interval: 10 * time.Millisecond,
+		Timeout:  time.Second,
+		Fall:     2,
+		Rise:     1,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	checker.Start(ctx)
+	defer checker.Stop()
+
+	deadline := time.After(time.Second)
+	for balancer.isHealthy() {
+		select {
+		case <-deadline:
+			t.Fatal("backend was never marked unhealthy")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	if checker.Gauge(target) != 0 {
+		t.Errorf("expected gauge 0 for unhealthy backend, got %v", checker.Gauge(target))
+	}
+}
+
+func TestHealthCheckerRestoresAfterRiseSuccesses(t *testing.T) {
+	server := scriptedServer(t, []int{500, 200, 200})
+	defer server.Close()
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+
+	balancer := &fakeBalancer{healthy: false}
+	checker := New(balancer, []*url.URL{target}, Config{
+		Interval: 10 * time.Millisecond,
+		Timeout:  time.Second,
+		Fall:     1,
+		Rise:     2,
+	})
+	// Seed the checker's own view as unhealthy so the first couple of
+	// successes are needed to restore it, mirroring a backend that
+	// started the test already ejected.
+	checker.states[target.String()].healthy = false
+	checker.gauges[target.String()] = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	checker.Start(ctx)
+	defer checker.Stop()
+
+	deadline := time.After(time.Second)
+	for !balancer.isHealthy() {
+		select {
+		case <-deadline:
+			t.Fatal("backend was never restored")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	if checker.Gauge(target) != 1 {
+		t.Errorf("expected gauge 1 for healthy backend, got %v", checker.Gauge(target))
+	}
+}
+
+func TestConfigAcceptsDefaultStatusCodes(t *testing.T) {
+	cfg := Config{}.withDefaults()
+
+	for _, code := range []int{200, 204, 301, 399} {
+		if !cfg.accepts(code) {
+			t.Errorf("expected default config to accept status %d", code)
+		}
+	}
+	for _, code := range []int{400, 500} {
+		if cfg.accepts(code) {
+			t.Errorf("expected default config to reject status %d", code)
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"loadbalance/request"
+)
+
+// Config controls how a Server proxies requests to the backends behind its
+// Balancer.
+type Config struct {
+	// Addr is the address the Server listens on, e.g. ":8080".
+	Addr string
+
+	// MaxIdleConnsPerHost, DialTimeout, KeepAlive and TLSClientConfig tune
+	// the Transport used to reach backends.
+	MaxIdleConnsPerHost int
+	DialTimeout         time.Duration
+	KeepAlive           time.Duration
+	TLSClientConfig     *tls.Config
+
+	// MaxRetries is how many additional backends are tried for an
+	// idempotent request after the first one fails. Defaults to 2.
+	MaxRetries int
+
+	// HedgeDelay, if positive, enables hedged requests for idempotent
+	// methods: once HedgeDelay has elapsed without a response from the
+	// first backend, a second request is sent to another backend and
+	// whichever responds first wins; the other is cancelled.
+	HedgeDelay time.Duration
+
+	// DrainTimeout bounds how long Shutdown waits for in-flight requests
+	// to finish once the listener stops accepting new connections.
+	// Defaults to 30s.
+	DrainTimeout time.Duration
+}
+
+const (
+	defaultMaxIdleConnsPerHost = 100
+	defaultDialTimeout         = 5 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultMaxRetries          = 2
+	defaultDrainTimeout        = 30 * time.Second
+)
+
+func (cfg Config) withDefaults() Config {
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = defaultDialTimeout
+	}
+	if cfg.KeepAlive <= 0 {
+		cfg.KeepAlive = defaultKeepAlive
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = defaultDrainTimeout
+	}
+	return cfg
+}
+
+// Server is an L7 load balancer: it listens on Config.Addr and proxies
+// every request to a backend chosen by its Balancer, retrying idempotent
+// requests against other backends on failure.
+type Server struct {
+	cfg       Config
+	balancer  request.Balancer
+	transport *http.Transport
+	httpSrv   *http.Server
+}
+
+// NewServer builds a Server that proxies to the backends in balancer.
+func NewServer(balancer request.Balancer, cfg Config) *Server {
+	cfg = cfg.withDefaults()
+
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: cfg.KeepAlive}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		TLSClientConfig:     cfg.TLSClientConfig,
+	}
+
+	s := &Server{
+		cfg:       cfg,
+		balancer:  balancer,
+		transport: transport,
+	}
+	s.httpSrv = &http.Server{
+		Addr:    cfg.Addr,
+		Handler: s,
+	}
+	return s
+}
+
+// ListenAndServe starts accepting connections; it blocks until the server
+// is shut down, returning http.ErrServerClosed in the normal case.
+func (s *Server) ListenAndServe() error {
+	return s.httpSrv.ListenAndServe()
+}
+
+// Shutdown stops accepting new connections and waits for in-flight
+// requests to finish, up to Config.DrainTimeout.
+func (s *Server) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.DrainTimeout)
+	defer cancel()
+	return s.httpSrv.Shutdown(ctx)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.HedgeDelay > 0 && isIdempotent(r.Method) {
+		s.serveHedged(w, r)
+		return
+	}
+	s.serveWithRetry(w, r)
+}
+
+// serveWithRetry proxies r to a backend chosen by the Balancer, retrying
+// against different backends on failure. Only idempotent methods are
+// retried; a failed non-idempotent request is reported immediately.
+func (s *Server) serveWithRetry(w http.ResponseWriter, r *http.Request) {
+	body, err := bufferBody(r)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	tried := make(map[string]struct{})
+	attempts := s.cfg.MaxRetries + 1
+	if !isIdempotent(r.Method) {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		target := s.pickUntried(r, tried)
+		if target == nil {
+			break
+		}
+		tried[target.String()] = struct{}{}
+
+		req := r.Clone(r.Context())
+		req.Body = body()
+
+		sw := newStreamingWriter(w)
+		ok := s.proxyOnce(sw, req, target)
+		s.reportOutcome(target, ok)
+		if ok || sw.committed {
+			return
+		}
+		lastErr = fmt.Errorf("backend %s did not respond", target)
+	}
+
+	log.Printf("proxy: all backends failed for %s %s: %v", r.Method, r.URL, lastErr)
+	http.Error(w, "bad gateway", http.StatusBadGateway)
+}
+
+type hedgeResult struct {
+	rec *bufferedWriter
+	ok  bool
+}
+
+// serveHedged proxies r to the first backend the Balancer picks, and if
+// HedgeDelay elapses before it responds, also dispatches to a second
+// backend; the first response wins and the loser's request is cancelled.
+func (s *Server) serveHedged(w http.ResponseWriter, r *http.Request) {
+	body, err := bufferBody(r)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	primary := s.balancer.Next(r)
+	if primary == nil {
+		http.Error(w, "no backend available", http.StatusBadGateway)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	pending := 0
+	tried := map[string]struct{}{}
+
+	launch := func(target *url.URL) {
+		tried[target.String()] = struct{}{}
+		pending++
+		req := r.Clone(ctx)
+		req.Body = body()
+		go func() {
+			rec := newBufferedWriter()
+			ok := s.proxyOnce(rec, req, target)
+			s.reportOutcome(target, ok)
+			results <- hedgeResult{rec, ok}
+		}()
+	}
+
+	launch(primary)
+
+	timer := time.NewTimer(s.cfg.HedgeDelay)
+	defer timer.Stop()
+
+	for pending > 0 {
+		select {
+		case res := <-results:
+			pending--
+			if res.ok {
+				copyBuffered(w, res.rec)
+				return
+			}
+		case <-timer.C:
+			if backup := s.pickUntried(r, tried); backup != nil {
+				launch(backup)
+			}
+		}
+	}
+
+	http.Error(w, "bad gateway", http.StatusBadGateway)
+}
+
+// proxyOnce sends req to target via httputil.ReverseProxy, writing the
+// response to w. It reports false if the backend could not be reached.
+func (s *Server) proxyOnce(w http.ResponseWriter, req *http.Request, target *url.URL) bool {
+	ok := true
+	rp := &httputil.ReverseProxy{
+		Transport: s.transport,
+		Director: func(r *http.Request) {
+			r.URL.Scheme = target.Scheme
+			r.URL.Host = target.Host
+			r.Host = target.Host
+		},
+		ErrorHandler: func(http.ResponseWriter, *http.Request, error) {
+			ok = false
+		},
+	}
+	rp.ServeHTTP(w, req)
+	return ok
+}
+
+// pickUntried asks the Balancer for a backend not already in tried,
+// looking at most len(Servers())+1 times so a balancer that cycles
+// through a small pool doesn't loop forever.
+func (s *Server) pickUntried(r *http.Request, tried map[string]struct{}) *url.URL {
+	for i := 0; i < len(s.balancer.Servers())+1; i++ {
+		u := s.balancer.Next(r)
+		if u == nil {
+			return nil
+		}
+		if _, seen := tried[u.String()]; !seen {
+			return u
+		}
+	}
+	return nil
+}
+
+// reportOutcome tells the balancer how the attempt to target went, closing
+// out whatever bookkeeping Next did for it (e.g. circuit.Balancer's Allow
+// call). It is a no-op for balancers that don't implement Report.
+func (s *Server) reportOutcome(target *url.URL, success bool) {
+	if reporter, ok := s.balancer.(interface {
+		Report(u *url.URL, success bool)
+	}); ok {
+		reporter.Report(target, success)
+	}
+}
+
+// streamingWriter defers committing a backend's response to the real
+// ResponseWriter until the backend has actually returned one, so a dial
+// failure or timeout can still be retried against another backend. Once a
+// status code is set, headers are flushed to the real ResponseWriter and
+// the body streams straight through instead of being buffered in memory -
+// unlike bufferedWriter, it cannot be replayed, so once committed is true
+// the attempt is final and must not be retried.
+type streamingWriter struct {
+	real      http.ResponseWriter
+	header    http.Header
+	committed bool
+}
+
+func newStreamingWriter(w http.ResponseWriter) *streamingWriter {
+	return &streamingWriter{real: w, header: make(http.Header)}
+}
+
+func (s *streamingWriter) Header() http.Header {
+	if s.committed {
+		return s.real.Header()
+	}
+	return s.header
+}
+
+func (s *streamingWriter) WriteHeader(code int) {
+	if s.committed {
+		return
+	}
+	dst := s.real.Header()
+	for key, values := range s.header {
+		dst[key] = values
+	}
+	s.real.WriteHeader(code)
+	s.committed = true
+}
+
+func (s *streamingWriter) Write(p []byte) (int, error) {
+	if !s.committed {
+		s.WriteHeader(http.StatusOK)
+	}
+	return s.real.Write(p)
+}
+
+// bufferedWriter records a single backend attempt's response in memory so
+// serveHedged can race two backends and replay only the winner to the real
+// ResponseWriter. Unlike streamingWriter, this buffers the whole body, but
+// that's inherent to hedging: which response "wins" can't be known until
+// both are in hand.
+type bufferedWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func newBufferedWriter() *bufferedWriter {
+	return &bufferedWriter{header: make(http.Header), code: http.StatusOK}
+}
+
+func (b *bufferedWriter) Header() http.Header         { return b.header }
+func (b *bufferedWriter) WriteHeader(code int)        { b.code = code }
+func (b *bufferedWriter) Write(p []byte) (int, error) { return b.body.Write(p) }
+
+// copyBuffered replays a bufferedWriter's recorded response onto the real
+// ResponseWriter.
+func copyBuffered(w http.ResponseWriter, rec *bufferedWriter) {
+	dst := w.Header()
+	for key, values := range rec.header {
+		dst[key] = values
+	}
+	w.WriteHeader(rec.code)
+	_, _ = w.Write(rec.body.Bytes())
+}
+
+// bufferBody reads r's body once and returns a factory that produces a
+// fresh io.ReadCloser over the buffered bytes, so a request can be retried
+// against multiple backends.
+func bufferBody(r *http.Request) (func() io.ReadCloser, error) {
+	if r.Body == nil {
+		return func() io.ReadCloser { return http.NoBody }, nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+
+	return func() io.ReadCloser {
+		return io.NopCloser(bytes.NewReader(data))
+	}, nil
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
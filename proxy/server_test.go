@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"loadbalance/circuit"
+	"loadbalance/request"
+)
+
+func backendServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestServerProxiesToBackend(t *testing.T) {
+	backend := backendServer(t, "hello from backend")
+	defer backend.Close()
+
+	backendURL, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse backend URL: %v", err)
+	}
+
+	balancer := request.NewRoundRobin([]*url.URL{backendURL})
+	server := NewServer(balancer, Config{})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if rec.Body.String() != "hello from backend" {
+		t.Errorf("expected proxied body, got %q", rec.Body.String())
+	}
+}
+
+func TestServerRetriesAgainstOtherBackend(t *testing.T) {
+	deadBackend, err := url.Parse("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatalf("Failed to parse dead backend URL: %v", err)
+	}
+
+	live := backendServer(t, "hello from the live backend")
+	defer live.Close()
+
+	liveURL, err := url.Parse(live.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse live backend URL: %v", err)
+	}
+
+	balancer := request.NewRoundRobin([]*url.URL{deadBackend, liveURL})
+	server := NewServer(balancer, Config{MaxRetries: 2})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the retry to succeed with status 200, got %d", rec.Code)
+	}
+	if rec.Body.String() != "hello from the live backend" {
+		t.Errorf("expected the live backend's body, got %q", rec.Body.String())
+	}
+}
+
+func TestServerHedgeFallsBackToSecondBackend(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("slow"))
+	}))
+	defer slow.Close()
+
+	fast := backendServer(t, "fast")
+	defer fast.Close()
+
+	slowURL, err := url.Parse(slow.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse slow backend URL: %v", err)
+	}
+	fastURL, err := url.Parse(fast.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse fast backend URL: %v", err)
+	}
+
+	balancer := request.NewRoundRobin([]*url.URL{slowURL, fastURL})
+	server := NewServer(balancer, Config{HedgeDelay: 20 * time.Millisecond})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if rec.Body.String() != "fast" {
+		t.Errorf("expected the hedged request to win with %q, got %q", "fast", rec.Body.String())
+	}
+}
+
+func TestServerReportsOutcomesToCircuitBreaker(t *testing.T) {
+	deadBackend, err := url.Parse("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatalf("Failed to parse dead backend URL: %v", err)
+	}
+
+	balancer := circuit.NewBalancer(request.NewRoundRobin([]*url.URL{deadBackend}), circuit.Config{
+		WindowSize:       5,
+		MinRequests:      5,
+		FailureThreshold: 0.5,
+		CooldownDuration: time.Minute,
+	})
+	server := NewServer(balancer, Config{MaxRetries: 0})
+
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		server.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadGateway {
+			t.Fatalf("iteration %d: expected 502 from the dead backend, got %d", i, rec.Code)
+		}
+	}
+
+	// Each failed attempt should have been reported to the breaker; five
+	// failures trips it, so the balancer now denies the only backend it has.
+	if got := balancer.Next(httptest.NewRequest(http.MethodGet, "/", nil)); got != nil {
+		t.Errorf("expected the breaker to have opened and deny the backend, got %s", got)
+	}
+}
@@ -0,0 +1,60 @@
+package request
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// Balancer selects a backend URL for each request and tracks the pool of
+// servers a given strategy can route to. Implementations must be safe for
+// concurrent use.
+type Balancer interface {
+	// Next returns the backend to use for req, or nil if none are
+	// available.
+	Next(req *http.Request) *url.URL
+
+	// Add registers a backend, applying opts (e.g. Weight) to it. Adding a
+	// backend that is already present re-enables it if it had been
+	// removed or marked unhealthy.
+	Add(u *url.URL, opts ...ServerOption)
+
+	// Remove takes a backend out of rotation.
+	Remove(u *url.URL)
+
+	// MarkUnhealthy takes a backend out of rotation until it is re-Added,
+	// typically by a health checker.
+	MarkUnhealthy(u *url.URL)
+
+	// Servers returns the backends currently in rotation.
+	Servers() []*url.URL
+}
+
+// Server is a single backend as seen by a Balancer implementation.
+type Server struct {
+	URL    *url.URL
+	Weight int
+}
+
+// ServerOption configures a Server when it is added to a Balancer.
+type ServerOption func(*Server)
+
+// Weight sets the relative weight used by weight-aware strategies such as
+// WeightedRoundRobin. It is a no-op for strategies that ignore weight.
+func Weight(w int) ServerOption {
+	return func(s *Server) {
+		s.Weight = w
+	}
+}
+
+// parseServerURL re-parses u so the balancer owns its own copy, logging and
+// skipping it if invalid. This mirrors the defensive parsing the original
+// RoundRobinBalancer did at construction time.
+func parseServerURL(u *url.URL) (*url.URL, bool) {
+	parsed, err := url.Parse(u.String())
+	if err != nil {
+		log.Printf("Invalid URL %s: %v", u, err)
+		return nil, false
+	}
+	return parsed, true
+}
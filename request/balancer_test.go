@@ -0,0 +1,175 @@
+package request
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %s: %v", raw, err)
+	}
+	return u
+}
+
+func backendURLs(t *testing.T) []*url.URL {
+	t.Helper()
+	return []*url.URL{
+		mustParse(t, "http://localhost:81"),
+		mustParse(t, "http://localhost:82"),
+		mustParse(t, "http://localhost:83"),
+	}
+}
+
+func TestRoundRobinNext(t *testing.T) {
+	backends := backendURLs(t)
+	balancer := NewRoundRobin(backends)
+
+	for i, expected := range backends {
+		got := balancer.Next(nil)
+		if got == nil {
+			t.Fatalf("iteration %d: got nil URL", i)
+		}
+		if got.String() != expected.String() {
+			t.Errorf("iteration %d: expected %s, got %s", i, expected, got)
+		}
+	}
+}
+
+func TestRoundRobinMarkUnhealthyAndAdd(t *testing.T) {
+	backends := backendURLs(t)
+	balancer := NewRoundRobin(backends)
+
+	removed := backends[1]
+	balancer.MarkUnhealthy(removed)
+
+	for i := 0; i < len(backends)-1; i++ {
+		got := balancer.Next(nil)
+		if got == nil {
+			t.Fatalf("iteration %d: got nil URL", i)
+		}
+		if got.String() == removed.String() {
+			t.Errorf("unhealthy backend %s was still selected", removed)
+		}
+	}
+
+	balancer.Add(removed)
+	found := false
+	for _, u := range balancer.Servers() {
+		if u.String() == removed.String() {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected %s to be back in rotation after Add", removed)
+	}
+}
+
+func TestWeightedRoundRobinDistribution(t *testing.T) {
+	heavy := mustParse(t, "http://localhost:81")
+	light := mustParse(t, "http://localhost:82")
+
+	balancer := NewWeightedRoundRobin(nil)
+	balancer.Add(heavy, Weight(3))
+	balancer.Add(light, Weight(1))
+
+	counts := map[string]int{}
+	for i := 0; i < 8; i++ {
+		counts[balancer.Next(nil).String()]++
+	}
+
+	if counts[heavy.String()] != 6 || counts[light.String()] != 2 {
+		t.Errorf("expected a 3:1 split over 8 picks, got %v", counts)
+	}
+}
+
+func TestWeightedRoundRobinReAddWithoutWeightPreservesIt(t *testing.T) {
+	heavy := mustParse(t, "http://localhost:81")
+	light := mustParse(t, "http://localhost:82")
+
+	balancer := NewWeightedRoundRobin(nil)
+	balancer.Add(heavy, Weight(9))
+	balancer.Add(light, Weight(1))
+
+	// Re-Add a backend that is still registered with a bare Add, with no
+	// Weight option.
+	balancer.Add(heavy)
+
+	counts := map[string]int{}
+	for i := 0; i < 10; i++ {
+		counts[balancer.Next(nil).String()]++
+	}
+
+	if counts[heavy.String()] != 9 || counts[light.String()] != 1 {
+		t.Errorf("expected the 9:1 split to survive a bare re-Add, got %v", counts)
+	}
+}
+
+func TestRandomOnlyReturnsKnownBackends(t *testing.T) {
+	backends := backendURLs(t)
+	balancer := NewRandom(backends)
+
+	known := map[string]struct{}{}
+	for _, b := range backends {
+		known[b.String()] = struct{}{}
+	}
+
+	for i := 0; i < 20; i++ {
+		got := balancer.Next(nil)
+		if _, ok := known[got.String()]; !ok {
+			t.Fatalf("Next returned unknown backend %s", got)
+		}
+	}
+}
+
+func TestLeastConnectionsPrefersIdleBackend(t *testing.T) {
+	busy := mustParse(t, "http://localhost:81")
+	idle := mustParse(t, "http://localhost:82")
+
+	balancer := NewLeastConnections(nil)
+	balancer.Add(busy)
+	balancer.Add(idle)
+
+	// The first Next goes to busy and the second to idle; releasing idle
+	// leaves busy with 1 in-flight request and idle with 0.
+	balancer.Next(nil)
+	balancer.Next(nil)
+	balancer.Release(idle)
+
+	got := balancer.Next(nil)
+	if got.String() != idle.String() {
+		t.Errorf("expected the idle backend %s to be chosen, got %s", idle, got)
+	}
+}
+
+func TestConsistentHashStableForSameKey(t *testing.T) {
+	backends := backendURLs(t)
+	balancer := NewConsistentHash(backends, WithHashKeyFunc(func(r *http.Request) string {
+		return "client-a"
+	}))
+
+	first := balancer.Next(nil)
+	for i := 0; i < 10; i++ {
+		if got := balancer.Next(nil); got.String() != first.String() {
+			t.Fatalf("expected the same backend %s for a stable key, got %s", first, got)
+		}
+	}
+}
+
+func TestConsistentHashSkipsRemovedBackend(t *testing.T) {
+	backends := backendURLs(t)
+	balancer := NewConsistentHash(backends, WithHashKeyFunc(func(r *http.Request) string {
+		return "client-b"
+	}))
+
+	picked := balancer.Next(nil)
+	balancer.MarkUnhealthy(picked)
+
+	got := balancer.Next(nil)
+	if got.String() == picked.String() {
+		t.Errorf("removed backend %s was still selected", picked)
+	}
+}
@@ -0,0 +1,164 @@
+package request
+
+import (
+	"hash/fnv"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// defaultVirtualNodes is the number of ring positions each backend gets
+// when no explicit count is configured.
+const defaultVirtualNodes = 160
+
+// HashKeyFunc extracts the key used to place a request on the hash ring.
+// The default, DefaultHashKeyFunc, uses the client IP.
+type HashKeyFunc func(r *http.Request) string
+
+// DefaultHashKeyFunc keys requests by the client IP, falling back to the
+// raw RemoteAddr if it cannot be split into host and port.
+func DefaultHashKeyFunc(r *http.Request) string {
+	if r == nil || r.RemoteAddr == "" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ConsistentHash is a Balancer that maps requests to backends using a hash
+// ring with virtual nodes, so that adding or removing a backend only
+// reshuffles a small fraction of keys. The backend chosen for a key is the
+// one whose ring position is the successor of hash(key).
+type ConsistentHash struct {
+	lock         sync.RWMutex
+	hashKeyFunc  HashKeyFunc
+	virtualNodes int
+	servers      map[string]*url.URL
+	ring         []uint32
+	ringServers  map[uint32]*url.URL
+}
+
+// ConsistentHashOption configures a ConsistentHash balancer at construction
+// time.
+type ConsistentHashOption func(*ConsistentHash)
+
+// WithHashKeyFunc overrides the function used to derive the ring key from
+// a request. The default is DefaultHashKeyFunc.
+func WithHashKeyFunc(f HashKeyFunc) ConsistentHashOption {
+	return func(c *ConsistentHash) {
+		c.hashKeyFunc = f
+	}
+}
+
+// WithVirtualNodes overrides the number of ring positions per backend.
+func WithVirtualNodes(n int) ConsistentHashOption {
+	return func(c *ConsistentHash) {
+		c.virtualNodes = n
+	}
+}
+
+// NewConsistentHash builds a ConsistentHash balancer over backendUrls.
+func NewConsistentHash(backendUrls []*url.URL, opts ...ConsistentHashOption) *ConsistentHash {
+	c := &ConsistentHash{
+		hashKeyFunc:  DefaultHashKeyFunc,
+		virtualNodes: defaultVirtualNodes,
+		servers:      make(map[string]*url.URL),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for _, backendURL := range backendUrls {
+		if parsed, ok := parseServerURL(backendURL); ok {
+			c.servers[parsed.String()] = parsed
+		}
+	}
+	c.rebuildRing()
+	return c
+}
+
+func fnv1a(s string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// rebuildRing recomputes the ring from c.servers. Callers must hold c.lock.
+func (c *ConsistentHash) rebuildRing() {
+	ring := make([]uint32, 0, len(c.servers)*c.virtualNodes)
+	ringServers := make(map[uint32]*url.URL, len(c.servers)*c.virtualNodes)
+
+	for key, server := range c.servers {
+		for i := 0; i < c.virtualNodes; i++ {
+			pos := fnv1a(virtualNodeKey(key, i))
+			ring = append(ring, pos)
+			ringServers[pos] = server
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i] < ring[j] })
+
+	c.ring = ring
+	c.ringServers = ringServers
+}
+
+func virtualNodeKey(serverKey string, index int) string {
+	return serverKey + "#" + strconv.Itoa(index)
+}
+
+func (c *ConsistentHash) Next(req *http.Request) *url.URL {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if len(c.ring) == 0 {
+		return nil
+	}
+
+	hash := fnv1a(c.hashKeyFunc(req))
+	i := sort.Search(len(c.ring), func(i int) bool { return c.ring[i] >= hash })
+	if i == len(c.ring) {
+		i = 0
+	}
+	return c.ringServers[c.ring[i]]
+}
+
+func (c *ConsistentHash) Add(u *url.URL, _ ...ServerOption) {
+	parsed, ok := parseServerURL(u)
+	if !ok {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.servers[parsed.String()] = parsed
+	c.rebuildRing()
+}
+
+func (c *ConsistentHash) Remove(u *url.URL) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.servers, u.String())
+	c.rebuildRing()
+}
+
+func (c *ConsistentHash) MarkUnhealthy(u *url.URL) {
+	c.Remove(u)
+}
+
+func (c *ConsistentHash) Servers() []*url.URL {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	servers := make([]*url.URL, 0, len(c.servers))
+	for _, u := range c.servers {
+		servers = append(servers, u)
+	}
+	return servers
+}
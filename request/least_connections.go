@@ -0,0 +1,108 @@
+package request
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+)
+
+// connServer tracks the in-flight request count for a single backend.
+type connServer struct {
+	url      *url.URL
+	inFlight atomic.Int64
+}
+
+// LeastConnections is a Balancer that routes each request to the backend
+// with the fewest in-flight requests. Next increments the chosen backend's
+// counter; callers must call Release once the request completes so the
+// counter is decremented again.
+type LeastConnections struct {
+	lock    sync.Mutex
+	servers []*connServer
+}
+
+// NewLeastConnections builds a LeastConnections balancer over backendUrls.
+func NewLeastConnections(backendUrls []*url.URL) *LeastConnections {
+	l := &LeastConnections{}
+	for _, backendURL := range backendUrls {
+		l.Add(backendURL)
+	}
+	return l
+}
+
+func (l *LeastConnections) Next(_ *http.Request) *url.URL {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if len(l.servers) == 0 {
+		return nil
+	}
+
+	best := l.servers[0]
+	for _, s := range l.servers[1:] {
+		if s.inFlight.Load() < best.inFlight.Load() {
+			best = s
+		}
+	}
+	best.inFlight.Add(1)
+	return best.url
+}
+
+// Release decrements the in-flight count for u once a request dispatched
+// to it has completed. It is a no-op if u is not currently registered.
+func (l *LeastConnections) Release(u *url.URL) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, s := range l.servers {
+		if s.url.String() == u.String() {
+			s.inFlight.Add(-1)
+			return
+		}
+	}
+}
+
+func (l *LeastConnections) Add(u *url.URL, _ ...ServerOption) {
+	parsed, ok := parseServerURL(u)
+	if !ok {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, existing := range l.servers {
+		if existing.url.String() == parsed.String() {
+			return
+		}
+	}
+	l.servers = append(l.servers, &connServer{url: parsed})
+}
+
+func (l *LeastConnections) Remove(u *url.URL) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for i, existing := range l.servers {
+		if existing.url.String() == u.String() {
+			l.servers = append(l.servers[:i], l.servers[i+1:]...)
+			break
+		}
+	}
+}
+
+func (l *LeastConnections) MarkUnhealthy(u *url.URL) {
+	l.Remove(u)
+}
+
+func (l *LeastConnections) Servers() []*url.URL {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	servers := make([]*url.URL, len(l.servers))
+	for i, s := range l.servers {
+		servers[i] = s.url
+	}
+	return servers
+}
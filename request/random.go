@@ -0,0 +1,75 @@
+package request
+
+import (
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Random is a Balancer that picks a backend uniformly at random on every
+// call to Next.
+type Random struct {
+	lock       sync.Mutex
+	activeUrls []*url.URL
+}
+
+// NewRandom builds a Random balancer over backendUrls.
+func NewRandom(backendUrls []*url.URL) *Random {
+	activeUrls := make([]*url.URL, 0, len(backendUrls))
+	for _, backendURL := range backendUrls {
+		if parsed, ok := parseServerURL(backendURL); ok {
+			activeUrls = append(activeUrls, parsed)
+		}
+	}
+
+	return &Random{activeUrls: activeUrls}
+}
+
+func (r *Random) Next(_ *http.Request) *url.URL {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(r.activeUrls) == 0 {
+		return nil
+	}
+
+	return r.activeUrls[rand.Intn(len(r.activeUrls))]
+}
+
+func (r *Random) Add(u *url.URL, _ ...ServerOption) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, existing := range r.activeUrls {
+		if existing.String() == u.String() {
+			return
+		}
+	}
+	r.activeUrls = append(r.activeUrls, u)
+}
+
+func (r *Random) Remove(u *url.URL) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, existing := range r.activeUrls {
+		if existing.String() == u.String() {
+			r.activeUrls = append(r.activeUrls[:i], r.activeUrls[i+1:]...)
+			break
+		}
+	}
+}
+
+func (r *Random) MarkUnhealthy(u *url.URL) {
+	r.Remove(u)
+}
+
+func (r *Random) Servers() []*url.URL {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	servers := make([]*url.URL, len(r.activeUrls))
+	copy(servers, r.activeUrls)
+	return servers
+}
@@ -0,0 +1,77 @@
+package request
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+)
+
+// RoundRobin is a Balancer that cycles through its backends in order.
+type RoundRobin struct {
+	lock         sync.Mutex
+	activeUrls   []*url.URL
+	currentIndex uint64
+}
+
+// NewRoundRobin builds a RoundRobin balancer over backendUrls.
+func NewRoundRobin(backendUrls []*url.URL) *RoundRobin {
+	activeUrls := make([]*url.URL, 0, len(backendUrls))
+	for _, backendURL := range backendUrls {
+		if parsed, ok := parseServerURL(backendURL); ok {
+			activeUrls = append(activeUrls, parsed)
+		}
+	}
+
+	return &RoundRobin{activeUrls: activeUrls}
+}
+
+func (r *RoundRobin) Next(_ *http.Request) *url.URL {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if len(r.activeUrls) == 0 {
+		return nil
+	}
+
+	index := atomic.LoadUint64(&r.currentIndex)
+	atomic.AddUint64(&r.currentIndex, 1)
+	return r.activeUrls[index%uint64(len(r.activeUrls))]
+}
+
+func (r *RoundRobin) Add(u *url.URL, _ ...ServerOption) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, existing := range r.activeUrls {
+		if existing.String() == u.String() {
+			return
+		}
+	}
+	r.activeUrls = append(r.activeUrls, u)
+}
+
+func (r *RoundRobin) Remove(u *url.URL) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, existing := range r.activeUrls {
+		if existing.String() == u.String() {
+			r.activeUrls = append(r.activeUrls[:i], r.activeUrls[i+1:]...)
+			break
+		}
+	}
+}
+
+func (r *RoundRobin) MarkUnhealthy(u *url.URL) {
+	r.Remove(u)
+}
+
+func (r *RoundRobin) Servers() []*url.URL {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	servers := make([]*url.URL, len(r.activeUrls))
+	copy(servers, r.activeUrls)
+	return servers
+}
@@ -0,0 +1,114 @@
+package request
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// weightedServer tracks the smooth weighted round-robin bookkeeping for a
+// single backend, in addition to its static weight.
+type weightedServer struct {
+	url           *url.URL
+	weight        int
+	currentWeight int
+}
+
+// WeightedRoundRobin is a Balancer that distributes requests across
+// backends proportionally to their configured weight, using the smooth
+// weighted round-robin algorithm (as used by nginx and vulcand/oxy's
+// roundrobin.Weight option). Backends added without a Weight option get
+// the default weight of 1.
+type WeightedRoundRobin struct {
+	lock    sync.Mutex
+	servers []*weightedServer
+}
+
+// NewWeightedRoundRobin builds a WeightedRoundRobin balancer over
+// backendUrls, all at the default weight of 1. Use Add with the Weight
+// option to register backends with a non-default weight.
+func NewWeightedRoundRobin(backendUrls []*url.URL) *WeightedRoundRobin {
+	w := &WeightedRoundRobin{}
+	for _, backendURL := range backendUrls {
+		w.Add(backendURL)
+	}
+	return w
+}
+
+func (w *WeightedRoundRobin) Next(_ *http.Request) *url.URL {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if len(w.servers) == 0 {
+		return nil
+	}
+
+	total := 0
+	var best *weightedServer
+	for _, s := range w.servers {
+		s.currentWeight += s.weight
+		total += s.weight
+		if best == nil || s.currentWeight > best.currentWeight {
+			best = s
+		}
+	}
+	best.currentWeight -= total
+	return best.url
+}
+
+func (w *WeightedRoundRobin) Add(u *url.URL, opts ...ServerOption) {
+	parsed, ok := parseServerURL(u)
+	if !ok {
+		return
+	}
+
+	server := &Server{URL: parsed, Weight: 1}
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for _, existing := range w.servers {
+		if existing.url.String() == parsed.String() {
+			// Re-Adding a still-registered backend with no Weight option
+			// keeps its configured weight. MarkUnhealthy and Remove drop a
+			// backend together with its weight, so a backend restored by a
+			// bare Add after either (as healthcheck.HealthChecker does)
+			// comes back at the default weight of 1.
+			if len(opts) > 0 {
+				existing.weight = server.Weight
+			}
+			return
+		}
+	}
+	w.servers = append(w.servers, &weightedServer{url: parsed, weight: server.Weight})
+}
+
+func (w *WeightedRoundRobin) Remove(u *url.URL) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for i, existing := range w.servers {
+		if existing.url.String() == u.String() {
+			w.servers = append(w.servers[:i], w.servers[i+1:]...)
+			break
+		}
+	}
+}
+
+func (w *WeightedRoundRobin) MarkUnhealthy(u *url.URL) {
+	w.Remove(u)
+}
+
+func (w *WeightedRoundRobin) Servers() []*url.URL {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	servers := make([]*url.URL, len(w.servers))
+	for i, s := range w.servers {
+		servers[i] = s.url
+	}
+	return servers
+}
@@ -0,0 +1,96 @@
+package sticky
+
+import (
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// hashVirtualNodes is the number of ring positions each backend gets when
+// pinning clients in HashMode.
+const hashVirtualNodes = 100
+
+// hashRing is a cached consistent-hash ring over a known set of backends,
+// tagged with ringKey so selectHash can tell whether the underlying
+// balancer's server set has changed since it was built.
+type hashRing struct {
+	key     string
+	ring    []uint32
+	servers map[uint32]*url.URL
+}
+
+// selectHash pins clients that don't carry (or accept) cookies by hashing
+// a request header over a ring built from the underlying balancer's
+// currently healthy backends. The ring is cached and only rebuilt when the
+// server set actually changes - the same way request.ConsistentHash caches
+// its ring across calls and only rebuilds on Add/Remove - so a pinned
+// backend being marked unhealthy still moves its clients elsewhere on
+// their very next request, without hashing the whole backend set again on
+// every request that doesn't change it.
+func (s *StickySession) selectHash(r *http.Request) *url.URL {
+	servers := s.underlying.Servers()
+	if len(servers) == 0 {
+		return nil
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].String() < servers[j].String() })
+
+	ring, ringServers := s.hashRingFor(servers)
+
+	key := r.Header.Get(s.cfg.HashHeader)
+	if key == "" {
+		key = r.RemoteAddr
+	}
+	hash := fnv1a(key)
+
+	i := sort.Search(len(ring), func(i int) bool { return ring[i] >= hash })
+	if i == len(ring) {
+		i = 0
+	}
+	return ringServers[ring[i]]
+}
+
+// hashRingFor returns the hash ring for servers (already sorted),
+// rebuilding it only if the server set differs from the last call.
+func (s *StickySession) hashRingFor(servers []*url.URL) ([]uint32, map[uint32]*url.URL) {
+	key := ringKey(servers)
+
+	s.hashMu.Lock()
+	defer s.hashMu.Unlock()
+
+	if s.hashCache.key == key {
+		return s.hashCache.ring, s.hashCache.servers
+	}
+
+	ring := make([]uint32, 0, len(servers)*hashVirtualNodes)
+	ringServers := make(map[uint32]*url.URL, len(servers)*hashVirtualNodes)
+	for _, server := range servers {
+		for i := 0; i < hashVirtualNodes; i++ {
+			pos := fnv1a(server.String() + "#" + strconv.Itoa(i))
+			ring = append(ring, pos)
+			ringServers[pos] = server
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i] < ring[j] })
+
+	s.hashCache = hashRing{key: key, ring: ring, servers: ringServers}
+	return ring, ringServers
+}
+
+// ringKey derives a cache key from servers (already sorted), so the same
+// server set in the same order always maps to the same key.
+func ringKey(servers []*url.URL) string {
+	parts := make([]string, len(servers))
+	for i, u := range servers {
+		parts[i] = u.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+func fnv1a(s string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum32()
+}
@@ -0,0 +1,42 @@
+package sticky
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// sign returns backendID with an HMAC-SHA256 signature appended, so it can
+// round-trip through a cookie and be verified later without server-side
+// state.
+func (s *StickySession) sign(backendID string) string {
+	mac := hmac.New(sha256.New, s.currentSecret())
+	mac.Write([]byte(backendID))
+	sig := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	return backendID + "." + sig
+}
+
+// verify checks value's signature and returns the backend ID it signs.
+func (s *StickySession) verify(value string) (string, bool) {
+	// Split on the last ".", not the first: backendID is a full backend
+	// URL and routinely contains dots of its own (IPs, hostnames), while
+	// the base64url-encoded signature never does.
+	i := strings.LastIndex(value, ".")
+	if i < 0 {
+		return "", false
+	}
+	backendID, sigStr := value[:i], value[i+1:]
+
+	sig, err := base64.RawURLEncoding.DecodeString(sigStr)
+	if err != nil {
+		return "", false
+	}
+
+	mac := hmac.New(sha256.New, s.currentSecret())
+	mac.Write([]byte(backendID))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return "", false
+	}
+	return backendID, true
+}
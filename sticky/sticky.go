@@ -0,0 +1,157 @@
+// Package sticky adds session affinity on top of a request.Balancer: once
+// a client has been routed to a backend, later requests from that same
+// client keep going to it as long as it stays healthy.
+package sticky
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"loadbalance/request"
+)
+
+// Mode selects how a client is recognized across requests.
+type Mode int
+
+const (
+	// CookieMode pins HTTP clients via a signed cookie.
+	CookieMode Mode = iota
+	// HashMode pins clients that don't carry cookies (or don't accept
+	// them) via a consistent hash over a request header, so the same
+	// client still lands on the same backend.
+	HashMode
+)
+
+const (
+	defaultCookieName = "lb_affinity"
+	defaultPath       = "/"
+	defaultHashHeader = "X-Forwarded-For"
+)
+
+// Config configures a StickySession.
+type Config struct {
+	Mode Mode
+
+	// CookieName is the cookie used in CookieMode. Defaults to
+	// "lb_affinity".
+	CookieName string
+	Path       string
+	MaxAge     int
+	Secure     bool
+	HTTPOnly   bool
+	SameSite   http.SameSite
+
+	// HashHeader is the request header hashed on in HashMode, e.g.
+	// "X-Forwarded-For". Defaults to "X-Forwarded-For"; falls back to
+	// r.RemoteAddr if the header is absent.
+	HashHeader string
+
+	// Secret is the HMAC key used to sign cookies. Rotate it with
+	// SetSecret; requests signed with a stale secret simply miss and are
+	// re-pinned rather than rejected.
+	Secret []byte
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.CookieName == "" {
+		cfg.CookieName = defaultCookieName
+	}
+	if cfg.Path == "" {
+		cfg.Path = defaultPath
+	}
+	if cfg.HashHeader == "" {
+		cfg.HashHeader = defaultHashHeader
+	}
+	return cfg
+}
+
+// StickySession wraps a request.Balancer, pinning each client to the
+// backend it was first routed to.
+type StickySession struct {
+	underlying request.Balancer
+	cfg        Config
+
+	mu     sync.RWMutex
+	secret []byte
+
+	hashMu    sync.Mutex
+	hashCache hashRing
+}
+
+// New builds a StickySession over underlying.
+func New(underlying request.Balancer, cfg Config) *StickySession {
+	cfg = cfg.withDefaults()
+	return &StickySession{
+		underlying: underlying,
+		cfg:        cfg,
+		secret:     cfg.Secret,
+	}
+}
+
+// SetSecret rotates the HMAC secret used to sign cookies. Cookies signed
+// with the previous secret fail verification on their next use and the
+// client is simply re-pinned, possibly to a different backend.
+func (s *StickySession) SetSecret(secret []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secret = secret
+}
+
+func (s *StickySession) currentSecret() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.secret
+}
+
+// Select returns the backend r should be routed to. In CookieMode it
+// reads (and, if necessary, writes) an affinity cookie on w; in HashMode w
+// is unused and the pin is derived purely from a consistent hash. In
+// CookieMode, if the client's previously pinned backend is no longer in
+// the underlying balancer's healthy set, Select falls back to the
+// underlying balancer and re-pins; in HashMode the hash is taken over the
+// remaining healthy backends instead.
+func (s *StickySession) Select(w http.ResponseWriter, r *http.Request) *url.URL {
+	if s.cfg.Mode == HashMode {
+		return s.selectHash(r)
+	}
+	return s.selectCookie(w, r)
+}
+
+func (s *StickySession) selectCookie(w http.ResponseWriter, r *http.Request) *url.URL {
+	if cookie, err := r.Cookie(s.cfg.CookieName); err == nil {
+		if backendID, ok := s.verify(cookie.Value); ok {
+			if target := s.healthyBackend(backendID); target != nil {
+				return target
+			}
+		}
+	}
+
+	target := s.underlying.Next(r)
+	if target == nil {
+		return nil
+	}
+	s.setCookie(w, target)
+	return target
+}
+
+func (s *StickySession) healthyBackend(backendID string) *url.URL {
+	for _, u := range s.underlying.Servers() {
+		if u.String() == backendID {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *StickySession) setCookie(w http.ResponseWriter, target *url.URL) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     s.cfg.CookieName,
+		Value:    s.sign(target.String()),
+		Path:     s.cfg.Path,
+		MaxAge:   s.cfg.MaxAge,
+		Secure:   s.cfg.Secure,
+		HttpOnly: s.cfg.HTTPOnly,
+		SameSite: s.cfg.SameSite,
+	})
+}
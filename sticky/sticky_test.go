@@ -0,0 +1,179 @@
+package sticky
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"loadbalance/request"
+)
+
+func mustParse(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("Failed to parse URL %s: %v", raw, err)
+	}
+	return u
+}
+
+func TestCookieModeStaysPinnedAcrossRotation(t *testing.T) {
+	backends := []*url.URL{
+		mustParse(t, "http://localhost:81"),
+		mustParse(t, "http://localhost:82"),
+		mustParse(t, "http://localhost:83"),
+	}
+	balancer := request.NewRoundRobin(backends)
+	session := New(balancer, Config{Secret: []byte("test-secret")})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	first := session.Select(rec, req)
+	if first == nil {
+		t.Fatal("expected a backend on the first request")
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected exactly one Set-Cookie, got %d", len(cookies))
+	}
+
+	// The balancer keeps rotating, but a client presenting the affinity
+	// cookie should stick to the same backend regardless.
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(cookies[0])
+
+		got := session.Select(rec, req)
+		if got == nil || got.String() != first.String() {
+			t.Fatalf("iteration %d: expected to stay pinned to %s, got %v", i, first, got)
+		}
+		if len(rec.Result().Cookies()) != 0 {
+			t.Errorf("iteration %d: did not expect a new cookie while still pinned", i)
+		}
+	}
+}
+
+func TestCookieModeFallsBackWhenPinnedBackendUnhealthy(t *testing.T) {
+	backends := []*url.URL{
+		mustParse(t, "http://localhost:81"),
+		mustParse(t, "http://localhost:82"),
+	}
+	balancer := request.NewRoundRobin(backends)
+	session := New(balancer, Config{Secret: []byte("test-secret")})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	first := session.Select(rec, req)
+	cookie := rec.Result().Cookies()[0]
+
+	balancer.MarkUnhealthy(first)
+
+	rec2 := httptest.NewRecorder()
+	req2 := httptest.NewRequest(http.MethodGet, "/", nil)
+	req2.AddCookie(cookie)
+
+	got := session.Select(rec2, req2)
+	if got == nil {
+		t.Fatal("expected a fallback backend")
+	}
+	if got.String() == first.String() {
+		t.Errorf("expected to fall back away from the unhealthy backend %s", first)
+	}
+	if len(rec2.Result().Cookies()) != 1 {
+		t.Error("expected a fresh cookie to be issued on fallback")
+	}
+}
+
+func TestCookieModeRejectsTamperedCookie(t *testing.T) {
+	backends := []*url.URL{mustParse(t, "http://localhost:81")}
+	balancer := request.NewRoundRobin(backends)
+	session := New(balancer, Config{Secret: []byte("test-secret")})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: defaultCookieName, Value: "http://localhost:81.bogus-signature"})
+
+	got := session.Select(rec, req)
+	if got == nil {
+		t.Fatal("expected a fallback backend for a tampered cookie")
+	}
+	if len(rec.Result().Cookies()) != 1 {
+		t.Error("expected a freshly signed cookie to replace the tampered one")
+	}
+}
+
+func TestCookieModeStaysPinnedWithDottedBackendHost(t *testing.T) {
+	backends := []*url.URL{
+		mustParse(t, "http://10.0.0.1:8080"),
+		mustParse(t, "http://10.0.0.2:8080"),
+	}
+	balancer := request.NewRoundRobin(backends)
+	session := New(balancer, Config{Secret: []byte("test-secret")})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	first := session.Select(rec, req)
+	if first == nil {
+		t.Fatal("expected a backend on the first request")
+	}
+	cookie := rec.Result().Cookies()[0]
+
+	rec2 := httptest.NewRecorder()
+	req2 := httptest.NewRequest(http.MethodGet, "/", nil)
+	req2.AddCookie(cookie)
+
+	got := session.Select(rec2, req2)
+	if got == nil || got.String() != first.String() {
+		t.Fatalf("expected to stay pinned to %s, got %v", first, got)
+	}
+	if len(rec2.Result().Cookies()) != 0 {
+		t.Error("did not expect a new cookie while still pinned")
+	}
+}
+
+func TestHashModePinsSameKeyToSameBackend(t *testing.T) {
+	backends := []*url.URL{
+		mustParse(t, "http://localhost:81"),
+		mustParse(t, "http://localhost:82"),
+		mustParse(t, "http://localhost:83"),
+	}
+	balancer := request.NewRoundRobin(backends)
+	session := New(balancer, Config{Mode: HashMode})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+	first := session.Select(nil, req)
+	for i := 0; i < 5; i++ {
+		got := session.Select(nil, req)
+		if got.String() != first.String() {
+			t.Fatalf("iteration %d: expected the same backend %s, got %s", i, first, got)
+		}
+	}
+}
+
+func TestHashModeDegradesWhenPinnedBackendRemoved(t *testing.T) {
+	backends := []*url.URL{
+		mustParse(t, "http://localhost:81"),
+		mustParse(t, "http://localhost:82"),
+	}
+	balancer := request.NewRoundRobin(backends)
+	session := New(balancer, Config{Mode: HashMode})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+
+	pinned := session.Select(nil, req)
+	balancer.MarkUnhealthy(pinned)
+
+	got := session.Select(nil, req)
+	if got == nil {
+		t.Fatal("expected a fallback backend")
+	}
+	if got.String() == pinned.String() {
+		t.Errorf("expected to move off the removed backend %s", pinned)
+	}
+}